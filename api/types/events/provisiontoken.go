@@ -0,0 +1,67 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events defines the audit event payloads emitted by Teleport.
+package events
+
+import "time"
+
+// AuditEvent is implemented by every audit event payload.
+type AuditEvent interface {
+	// GetType returns the event type.
+	GetType() string
+	// GetCode returns the event code.
+	GetCode() string
+}
+
+// Metadata is common metadata embedded in every audit event.
+type Metadata struct {
+	// Type is the event type, e.g. "provision_token.expire".
+	Type string `json:"event"`
+	// Code is the unique event code, e.g. "PT001I".
+	Code string `json:"code"`
+	// Time is when the event occurred.
+	Time time.Time `json:"time"`
+}
+
+// GetType returns the event type.
+func (m Metadata) GetType() string {
+	return m.Type
+}
+
+// GetCode returns the event code.
+func (m Metadata) GetCode() string {
+	return m.Code
+}
+
+// ResourceMetadata identifies the resource an event is about.
+type ResourceMetadata struct {
+	// Name is the name of the resource.
+	Name string `json:"name"`
+	// Expires is the resource's expiry time, if any.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// ProvisionTokenExpire is emitted when the static tokens reconciler prunes
+// an expired provision token.
+type ProvisionTokenExpire struct {
+	// Metadata is common event metadata.
+	Metadata
+	// ResourceMetadata identifies the expired token.
+	ResourceMetadata
+	// Roles are the roles the expired token granted.
+	Roles []string `json:"roles"`
+}
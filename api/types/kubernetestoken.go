@@ -0,0 +1,165 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// KubernetesBootstrapTokenSecretType is the Secret type Kubernetes uses
+	// for bootstrap tokens. A ProvisionToken can be sourced from any Secret
+	// of this type living in the configured namespace.
+	KubernetesBootstrapTokenSecretType = "bootstrap.kubernetes.io/token"
+
+	// KubernetesBootstrapTokenIDLength is the length of the public token-id
+	// portion of a Kubernetes-style bootstrap token.
+	KubernetesBootstrapTokenIDLength = 6
+	// KubernetesBootstrapTokenSecretLength is the length of the secret
+	// portion of a Kubernetes-style bootstrap token.
+	KubernetesBootstrapTokenSecretLength = 16
+
+	// kubernetesBootstrapTokenSecretNamePrefix is the prefix Kubernetes uses
+	// for the name of a bootstrap token Secret, i.e. "bootstrap-token-<id>".
+	kubernetesBootstrapTokenSecretNamePrefix = "bootstrap-token-"
+
+	// The following are the well-known data keys of a bootstrap token Secret,
+	// as defined by the Kubernetes cluster-bootstrap API.
+	kubernetesSecretKeyTokenID         = "token-id"
+	kubernetesSecretKeyTokenSecret     = "token-secret"
+	kubernetesSecretKeyExpiration      = "expiration"
+	kubernetesSecretKeyAuthExtraGroups = "auth-extra-groups"
+	kubernetesSecretKeyUsagePrefix     = "usage-"
+)
+
+// kubernetesBootstrapTokenRe matches a Kubernetes-style bootstrap token of
+// the form "<token-id>.<token-secret>", e.g. "abcdef.0123456789abcdef".
+var kubernetesBootstrapTokenRe = regexp.MustCompile(
+	`^[a-z0-9]{6}\.[a-z0-9]{16}$`,
+)
+
+// IsKubernetesBootstrapToken returns true if token is formatted as a
+// Kubernetes-style bootstrap token ("[a-z0-9]{6}.[a-z0-9]{16}").
+func IsKubernetesBootstrapToken(token string) bool {
+	return kubernetesBootstrapTokenRe.MatchString(token)
+}
+
+// ParseKubernetesBootstrapToken splits a Kubernetes-style bootstrap token
+// into its public token-id and secret halves, validating the format of
+// both. The token-id is what callers should use to look up the backing
+// Kubernetes Secret, named "bootstrap-token-<id>".
+func ParseKubernetesBootstrapToken(token string) (id string, secret string, err error) {
+	if !IsKubernetesBootstrapToken(token) {
+		return "", "", trace.BadParameter("token is not a valid Kubernetes bootstrap token")
+	}
+	parts := strings.SplitN(token, ".", 2)
+	return parts[0], parts[1], nil
+}
+
+// KubernetesBootstrapTokenSecretName returns the conventional name of the
+// Kubernetes Secret backing the bootstrap token with the given id.
+func KubernetesBootstrapTokenSecretName(id string) string {
+	return kubernetesBootstrapTokenSecretNamePrefix + id
+}
+
+// NewProvisionTokenFromKubernetesSecret builds a ProvisionToken from the
+// data of a Kubernetes Secret of type bootstrap.kubernetes.io/token, as
+// found in the configured KubernetesTokenSource namespace. usageRoles maps
+// the suffix of a bootstrap token "usage-*" key (e.g. "node-join" for
+// "usage-node-join") to the Teleport role it should grant when that key is
+// present and set to "true".
+func NewProvisionTokenFromKubernetesSecret(id string, data map[string][]byte, usageRoles map[string]SystemRole) (ProvisionToken, error) {
+	secret, ok := data[kubernetesSecretKeyTokenSecret]
+	if !ok || len(secret) != KubernetesBootstrapTokenSecretLength {
+		return nil, trace.BadParameter("kubernetes secret %v is missing a valid token-secret", KubernetesBootstrapTokenSecretName(id))
+	}
+	if len(id) != KubernetesBootstrapTokenIDLength {
+		return nil, trace.BadParameter("kubernetes bootstrap token id %q must be %v characters", id, KubernetesBootstrapTokenIDLength)
+	}
+	// The resulting "id.secret" becomes the ProvisionToken's name, which
+	// ImportKubernetesBootstrapToken/RemoveKubernetesBootstrapToken later
+	// parse back out with ParseKubernetesBootstrapToken to dedup/remove by
+	// id. Reject anything outside the Kubernetes bootstrap-token charset
+	// up front so such a token can never become an unparseable, orphaned
+	// entry that later Secret updates or deletes can't reach.
+	if !IsKubernetesBootstrapToken(id + "." + string(secret)) {
+		return nil, trace.BadParameter("kubernetes secret %v has a token-id/token-secret outside the [a-z0-9] charset", KubernetesBootstrapTokenSecretName(id))
+	}
+
+	spec := ProvisionTokenSpecV2{
+		Roles: rolesFromKubernetesSecret(data, usageRoles),
+	}
+
+	if groups, ok := data[kubernetesSecretKeyAuthExtraGroups]; ok {
+		spec.KubernetesAuthExtraGroups = strings.Split(string(groups), ",")
+	}
+
+	token, err := NewProvisionToken(id+"."+string(secret), spec.Roles, time.Time{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if expiration, ok := data[kubernetesSecretKeyExpiration]; ok {
+		expires, err := time.Parse(time.RFC3339, string(expiration))
+		if err != nil {
+			return nil, trace.BadParameter("invalid expiration on kubernetes secret %v: %v", KubernetesBootstrapTokenSecretName(id), err)
+		}
+		token.SetExpiry(expires)
+	}
+
+	return token, nil
+}
+
+// rolesFromKubernetesSecret derives the set of roles a bootstrap token
+// Secret grants by checking each "usage-*" key against usageRoles.
+func rolesFromKubernetesSecret(data map[string][]byte, usageRoles map[string]SystemRole) SystemRoles {
+	var roles SystemRoles
+	for key, role := range usageRoles {
+		if v, ok := data[kubernetesSecretKeyUsagePrefix+key]; ok && string(v) == "true" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// ExportKubernetesBootstrapTokenSecret renders p as the data payload of a
+// Kubernetes Secret of type bootstrap.kubernetes.io/token, the inverse of
+// NewProvisionTokenFromKubernetesSecret.
+func ExportKubernetesBootstrapTokenSecret(p ProvisionToken, usageRoles map[string]SystemRole) (id string, data map[string][]byte, err error) {
+	id, secret, err := ParseKubernetesBootstrapToken(p.GetName())
+	if err != nil {
+		return "", nil, trace.Wrap(err)
+	}
+
+	data = map[string][]byte{
+		kubernetesSecretKeyTokenID:     []byte(id),
+		kubernetesSecretKeyTokenSecret: []byte(secret),
+	}
+	if !p.Expiry().IsZero() {
+		data[kubernetesSecretKeyExpiration] = []byte(p.Expiry().Format(time.RFC3339))
+	}
+	for key, role := range usageRoles {
+		if p.GetRoles().Include(role) {
+			data[kubernetesSecretKeyUsagePrefix+key] = []byte("true")
+		}
+	}
+	return id, data, nil
+}
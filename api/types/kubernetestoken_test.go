@@ -0,0 +1,54 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvisionTokenFromKubernetesSecretRejectsInvalidCharset(t *testing.T) {
+	usageRoles := map[string]SystemRole{"node-join": RoleNode}
+
+	_, err := NewProvisionTokenFromKubernetesSecret("ABCDEF", map[string][]byte{
+		"token-secret":    []byte("0123456789abcdef"),
+		"usage-node-join": []byte("true"),
+	}, usageRoles)
+	require.Error(t, err, "uppercase token-id must be rejected")
+
+	_, err = NewProvisionTokenFromKubernetesSecret("abcdef", map[string][]byte{
+		"token-secret":    []byte("0123456789.abcde"),
+		"usage-node-join": []byte("true"),
+	}, usageRoles)
+	require.Error(t, err, "token-secret outside [a-z0-9] must be rejected")
+}
+
+func TestNewProvisionTokenFromKubernetesSecretRoundTrip(t *testing.T) {
+	usageRoles := map[string]SystemRole{"node-join": RoleNode}
+
+	token, err := NewProvisionTokenFromKubernetesSecret("abcdef", map[string][]byte{
+		"token-secret":    []byte("0123456789abcdef"),
+		"usage-node-join": []byte("true"),
+	}, usageRoles)
+	require.NoError(t, err)
+	require.True(t, IsKubernetesBootstrapToken(token.GetName()))
+
+	id, _, err := ParseKubernetesBootstrapToken(token.GetName())
+	require.NoError(t, err)
+	require.Equal(t, "abcdef", id)
+}
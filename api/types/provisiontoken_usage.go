@@ -0,0 +1,67 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"sync/atomic"
+
+	"github.com/gravitational/trace"
+)
+
+// GetMaxUses returns the maximum number of times this token may be
+// redeemed before it is considered used up, or 0 if it may be redeemed an
+// unlimited number of times.
+func (p *ProvisionTokenV2) GetMaxUses() int32 {
+	return p.Spec.MaxUses
+}
+
+// SetMaxUses sets the maximum number of times this token may be redeemed.
+func (p *ProvisionTokenV2) SetMaxUses(max int32) {
+	p.Spec.MaxUses = max
+}
+
+// GetUsedCount returns the number of times this token has already been
+// redeemed.
+func (p *ProvisionTokenV2) GetUsedCount() int32 {
+	return atomic.LoadInt32(&p.Spec.UsedCount)
+}
+
+// IsUsedUp returns true if the token has a MaxUses limit and has already
+// reached it, matching the ephemeral, single-use token model used by
+// kubeadm-style cluster joins.
+func (p *ProvisionTokenV2) IsUsedUp() bool {
+	max := p.GetMaxUses()
+	return max > 0 && p.GetUsedCount() >= max
+}
+
+// Redeem atomically increments the token's used count and reports whether
+// the redemption was allowed. A redemption past MaxUses is rejected with
+// trace.AccessDenied and leaves the used count unchanged. Once Redeem
+// returns false, the caller must reject the join attempt. A used-up token
+// stays in StaticTokens; the reconciler removes only expired tokens.
+func (p *ProvisionTokenV2) Redeem() (bool, error) {
+	max := p.GetMaxUses()
+	if max == 0 {
+		atomic.AddInt32(&p.Spec.UsedCount, 1)
+		return true, nil
+	}
+	if atomic.AddInt32(&p.Spec.UsedCount, 1) > max {
+		atomic.AddInt32(&p.Spec.UsedCount, -1)
+		return false, trace.AccessDenied("provision token %q has reached its maximum of %v use(s)", p.GetName(), max)
+	}
+	return true, nil
+}
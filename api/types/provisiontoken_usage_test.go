@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisionTokenV2Redeem(t *testing.T) {
+	tok := &ProvisionTokenV2{
+		Metadata: Metadata{Name: "abcdef.0123456789abcdef"},
+		Spec:     ProvisionTokenSpecV2{MaxUses: 2},
+	}
+
+	ok, err := tok.Redeem()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, tok.IsUsedUp())
+
+	ok, err = tok.Redeem()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, tok.IsUsedUp())
+
+	ok, err = tok.Redeem()
+	require.False(t, ok)
+	require.Error(t, err)
+	require.Equal(t, int32(2), tok.GetUsedCount())
+}
+
+func TestProvisionTokenV2RedeemUnlimited(t *testing.T) {
+	tok := &ProvisionTokenV2{Spec: ProvisionTokenSpecV2{}}
+	for i := 0; i < 5; i++ {
+		ok, err := tok.Redeem()
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.False(t, tok.IsUsedUp())
+}
+
+func TestProvisionTokenV2RedeemConcurrent(t *testing.T) {
+	tok := &ProvisionTokenV2{Spec: ProvisionTokenSpecV2{MaxUses: 10}}
+
+	var wg sync.WaitGroup
+	successes := make(chan bool, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, _ := tok.Redeem()
+			successes <- ok
+		}()
+	}
+	wg.Wait()
+	close(successes)
+
+	count := 0
+	for ok := range successes {
+		if ok {
+			count++
+		}
+	}
+	require.Equal(t, 10, count)
+}
@@ -157,6 +157,25 @@ func (c *StaticTokensV2) CheckAndSetDefaults() error {
 	return nil
 }
 
+// RemoveExpired removes tokens whose Expires time has passed, as judged by
+// clock, and returns the ones removed. It does not persist the change;
+// callers are responsible for re-saving the resource via the marshaler.
+func (c *StaticTokensV2) RemoveExpired(clock Clock) []ProvisionToken {
+	now := clock.Now()
+	var removed []ProvisionToken
+	kept := c.Spec.StaticTokens[:0]
+	for _, tok := range c.Spec.StaticTokens {
+		expires := tok.Expires
+		if !expires.IsZero() && expires.Before(now) {
+			removed = append(removed, ProvisionTokensFromV1([]ProvisionTokenV1{tok})...)
+			continue
+		}
+		kept = append(kept, tok)
+	}
+	c.Spec.StaticTokens = kept
+	return removed
+}
+
 // String represents a human readable version of static provisioning tokens.
 func (c *StaticTokensV2) String() string {
 	return fmt.Sprintf("StaticTokens(%v)", c.Spec.StaticTokens)
@@ -184,6 +203,12 @@ const StaticTokensSpecSchemaTemplate = `{
 					},
 					"token": {
 						"type": "string"
+					},
+					"max_uses": {
+						"type": "integer"
+					},
+					"used_count": {
+						"type": "integer"
 					}
 				}
 			}
@@ -208,6 +233,22 @@ func GetStaticTokensSchema(extensionSchema string) string {
 type StaticTokensMarshaler interface {
 	Marshal(c StaticTokens, opts ...MarshalOption) ([]byte, error)
 	Unmarshal(bytes []byte, opts ...MarshalOption) (StaticTokens, error)
+	// ImportKubernetesBootstrapToken merges a token sourced from a
+	// Kubernetes bootstrap-token Secret into current, replacing any
+	// existing token with the same id.
+	ImportKubernetesBootstrapToken(current StaticTokens, id string, data map[string][]byte, usageRoles map[string]SystemRole) (StaticTokens, error)
+	// ExportKubernetesBootstrapToken renders the static token identified by
+	// id as a Kubernetes bootstrap-token Secret payload.
+	ExportKubernetesBootstrapToken(current StaticTokens, id string, usageRoles map[string]SystemRole) (data map[string][]byte, err error)
+	// RemoveKubernetesBootstrapToken removes the static token sourced from
+	// the Kubernetes bootstrap-token Secret with the given id, if present.
+	RemoveKubernetesBootstrapToken(current StaticTokens, id string) (StaticTokens, error)
+	// ApplyJSONPatch applies an RFC 6902 JSON Patch document scoped to
+	// spec.static_tokens to current.
+	ApplyJSONPatch(current StaticTokens, patch []byte) (StaticTokens, error)
+	// ApplyMergePatch applies an RFC 7396 JSON Merge Patch document scoped
+	// to spec.static_tokens to current.
+	ApplyMergePatch(current StaticTokens, patch []byte) (StaticTokens, error)
 }
 
 type teleportStaticTokensMarshaler struct{}
@@ -271,6 +312,70 @@ func (t *teleportStaticTokensMarshaler) Marshal(c StaticTokens, opts ...MarshalO
 	}
 }
 
+// ImportKubernetesBootstrapToken merges a token sourced from a Kubernetes
+// bootstrap-token Secret into current, replacing any existing token with
+// the same id.
+func (t *teleportStaticTokensMarshaler) ImportKubernetesBootstrapToken(current StaticTokens, id string, data map[string][]byte, usageRoles map[string]SystemRole) (StaticTokens, error) {
+	imported, err := NewProvisionTokenFromKubernetesSecret(id, data, usageRoles)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	tokens := current.GetStaticTokens()
+	merged := make([]ProvisionToken, 0, len(tokens)+1)
+	for _, tok := range tokens {
+		tokID, _, err := ParseKubernetesBootstrapToken(tok.GetName())
+		if err == nil && tokID == id {
+			continue
+		}
+		merged = append(merged, tok)
+	}
+	merged = append(merged, imported)
+	current.SetStaticTokens(merged)
+
+	if err := current.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return current, nil
+}
+
+// ExportKubernetesBootstrapToken renders the static token identified by id
+// as a Kubernetes bootstrap-token Secret payload.
+func (t *teleportStaticTokensMarshaler) ExportKubernetesBootstrapToken(current StaticTokens, id string, usageRoles map[string]SystemRole) (map[string][]byte, error) {
+	for _, tok := range current.GetStaticTokens() {
+		tokID, _, err := ParseKubernetesBootstrapToken(tok.GetName())
+		if err != nil || tokID != id {
+			continue
+		}
+		_, data, err := ExportKubernetesBootstrapTokenSecret(tok, usageRoles)
+		return data, trace.Wrap(err)
+	}
+	return nil, trace.NotFound("no static token with kubernetes bootstrap id %q", id)
+}
+
+// RemoveKubernetesBootstrapToken removes the static token sourced from the
+// Kubernetes bootstrap-token Secret with the given id, if present. It is a
+// no-op, not an error, if no such token is found, since the caller (a
+// KubernetesTokenSource reacting to a Secret delete) cannot distinguish a
+// stale delete event from one it has already reconciled.
+func (t *teleportStaticTokensMarshaler) RemoveKubernetesBootstrapToken(current StaticTokens, id string) (StaticTokens, error) {
+	tokens := current.GetStaticTokens()
+	kept := make([]ProvisionToken, 0, len(tokens))
+	for _, tok := range tokens {
+		tokID, _, err := ParseKubernetesBootstrapToken(tok.GetName())
+		if err == nil && tokID == id {
+			continue
+		}
+		kept = append(kept, tok)
+	}
+	current.SetStaticTokens(kept)
+
+	if err := current.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return current, nil
+}
+
 var staticTokensMarshaler StaticTokensMarshaler = &teleportStaticTokensMarshaler{}
 
 // SetStaticTokensMarshaler sets the marshaler.
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"github.com/gravitational/teleport/api/utils"
+	"github.com/gravitational/trace"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// ContentTypeJSONPatch is the content-type for an RFC 6902 JSON Patch
+	// request against a StaticTokens resource.
+	ContentTypeJSONPatch = "application/json-patch+json"
+	// ContentTypeMergePatch is the content-type for an RFC 7396 JSON Merge
+	// Patch request against a StaticTokens resource.
+	ContentTypeMergePatch = "application/merge-patch+json"
+)
+
+// staticTokensPatchPath is the gjson/sjson path of the StaticTokens list
+// within a marshaled StaticTokensV2. Paths of its elements extend it, e.g.
+// "spec.static_tokens.0.expires".
+const staticTokensPatchPath = "spec.static_tokens"
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch document to current,
+// re-validates the result against GetStaticTokensSchema, and re-runs
+// CheckAndSetDefaults. Only the spec.static_tokens path may be modified;
+// operations targeting any other path are rejected so that gitops-style
+// diffs can't race on unrelated fields of the singleton resource.
+// Only the add, replace and remove operations are supported; add at an
+// array index overwrites the element there instead of inserting before it.
+func (t *teleportStaticTokensMarshaler) ApplyJSONPatch(current StaticTokens, patch []byte) (StaticTokens, error) {
+	ops, err := parseJSONPatchOps(patch)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, op := range ops {
+		if !isStaticTokensPath(jsonPointerToGJSONPath(op.Path)) {
+			return nil, trace.BadParameter("json patch path %q is outside spec.static_tokens", op.Path)
+		}
+	}
+
+	raw, err := t.Marshal(current)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	patched, err := applyJSONPatchOps(raw, ops)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return t.unmarshalValidated(patched)
+}
+
+// ApplyMergePatch applies an RFC 7396 JSON Merge Patch document scoped to
+// spec.static_tokens, re-validates the result, and re-runs
+// CheckAndSetDefaults. The patch is merged into spec, so it has the form
+// {"static_tokens": [...]}; any other key fails schema validation.
+func (t *teleportStaticTokensMarshaler) ApplyMergePatch(current StaticTokens, patch []byte) (StaticTokens, error) {
+	raw, err := t.Marshal(current)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	merged, err := mergeJSONPatch(raw, "spec", patch)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return t.unmarshalValidated(merged)
+}
+
+// unmarshalValidated unmarshals raw, validating it against
+// GetStaticTokensSchema and re-running CheckAndSetDefaults.
+func (t *teleportStaticTokensMarshaler) unmarshalValidated(raw []byte) (StaticTokens, error) {
+	return t.Unmarshal(raw)
+}
+
+// isStaticTokensPath returns true if the gjson/sjson path targets
+// spec.static_tokens or one of its elements.
+func isStaticTokensPath(path string) bool {
+	return path == staticTokensPatchPath || len(path) > len(staticTokensPatchPath) &&
+		path[:len(staticTokensPatchPath)] == staticTokensPatchPath &&
+		(path[len(staticTokensPatchPath)] == '.' || path[len(staticTokensPatchPath)] == '#')
+}
+
+// jsonPatchOp is a single RFC 6902 operation.
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// parseJSONPatchOps parses an RFC 6902 JSON Patch document.
+func parseJSONPatchOps(patch []byte) ([]jsonPatchOp, error) {
+	var ops []jsonPatchOp
+	if err := utils.FastUnmarshal(patch, &ops); err != nil {
+		return nil, trace.BadParameter("invalid json patch: %v", err)
+	}
+	return ops, nil
+}
+
+// applyJSONPatchOps applies ops to raw using sjson path addressing,
+// translating RFC 6902 "/a/b" pointers to gjson/sjson "a.b" paths.
+func applyJSONPatchOps(raw []byte, ops []jsonPatchOp) ([]byte, error) {
+	for _, op := range ops {
+		gpath := jsonPointerToGJSONPath(op.Path)
+		var err error
+		switch op.Op {
+		case "add", "replace":
+			raw, err = sjson.SetBytes(raw, gpath, op.Value)
+		case "remove":
+			raw, err = sjson.DeleteBytes(raw, gpath)
+		default:
+			return nil, trace.BadParameter("unsupported json patch op %q", op.Op)
+		}
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return raw, nil
+}
+
+// mergeJSONPatch applies an RFC 7396 merge patch to the object living at
+// path within raw. Only the patch's top-level keys are merged: a null
+// deletes the key and any other value, nested objects included, replaces
+// the existing value whole.
+func mergeJSONPatch(raw []byte, path string, patch []byte) ([]byte, error) {
+	var merge map[string]interface{}
+	if err := utils.FastUnmarshal(patch, &merge); err != nil {
+		return nil, trace.BadParameter("invalid merge patch: %v", err)
+	}
+	for k, v := range merge {
+		gpath := path + "." + k
+		var err error
+		if v == nil {
+			raw, err = sjson.DeleteBytes(raw, gpath)
+		} else {
+			raw, err = sjson.SetBytes(raw, gpath, v)
+		}
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return raw, nil
+}
+
+// jsonPointerToGJSONPath converts an RFC 6901 JSON Pointer such as
+// "/spec/static_tokens/0/expires" into the gjson/sjson dotted path
+// "spec.static_tokens.0.expires". The "~0" and "~1" escapes are not
+// decoded.
+func jsonPointerToGJSONPath(pointer string) string {
+	if len(pointer) > 0 && pointer[0] == '/' {
+		pointer = pointer[1:]
+	}
+	out := make([]byte, 0, len(pointer))
+	for i := 0; i < len(pointer); i++ {
+		if pointer[i] == '/' {
+			out = append(out, '.')
+		} else {
+			out = append(out, pointer[i])
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyJSONPatchRoundTrips(t *testing.T) {
+	token, err := NewProvisionToken("abcdef.0123456789abcdef", SystemRoles{RoleNode}, time.Time{})
+	require.NoError(t, err)
+
+	st, err := NewStaticTokens(StaticTokensSpecV2{
+		StaticTokens: ProvisionTokensToV1([]ProvisionToken{token}),
+	})
+	require.NoError(t, err)
+
+	marshaler, ok := GetStaticTokensMarshaler().(*teleportStaticTokensMarshaler)
+	require.True(t, ok)
+
+	newExpiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	patch := []byte(fmt.Sprintf(
+		`[{"op":"replace","path":"/spec/static_tokens/0/expires","value":%q}]`,
+		newExpiry.Format(time.RFC3339)))
+
+	patched, err := marshaler.ApplyJSONPatch(st, patch)
+	require.NoError(t, err)
+
+	tokens := patched.GetStaticTokens()
+	require.Len(t, tokens, 1)
+	require.Equal(t, newExpiry, tokens[0].Expiry().UTC())
+}
+
+func TestApplyJSONPatchRejectsPathOutsideStaticTokens(t *testing.T) {
+	marshaler, ok := GetStaticTokensMarshaler().(*teleportStaticTokensMarshaler)
+	require.True(t, ok)
+
+	st := DefaultStaticTokens()
+	patch := []byte(`[{"op":"replace","path":"/kind","value":"evil"}]`)
+
+	_, err := marshaler.ApplyJSONPatch(st, patch)
+	require.Error(t, err)
+}
+
+func TestApplyMergePatchRoundTrips(t *testing.T) {
+	token, err := NewProvisionToken("abcdef.0123456789abcdef", SystemRoles{RoleNode}, time.Time{})
+	require.NoError(t, err)
+
+	st, err := NewStaticTokens(StaticTokensSpecV2{
+		StaticTokens: ProvisionTokensToV1([]ProvisionToken{token}),
+	})
+	require.NoError(t, err)
+
+	marshaler, ok := GetStaticTokensMarshaler().(*teleportStaticTokensMarshaler)
+	require.True(t, ok)
+
+	newExpiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	patch := []byte(fmt.Sprintf(`{"static_tokens":[{"token":"abcdef.0123456789abcdef","roles":["Node"],"expires":%q}]}`,
+		newExpiry.Format(time.RFC3339)))
+
+	patched, err := marshaler.ApplyMergePatch(st, patch)
+	require.NoError(t, err)
+
+	tokens := patched.GetStaticTokens()
+	require.Len(t, tokens, 1)
+	require.Equal(t, newExpiry, tokens[0].Expiry().UTC())
+}
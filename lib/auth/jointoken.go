@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+)
+
+// CheckOrRedeemToken validates that token grants role, and, for tokens
+// with a MaxUses limit, redeems one use. It returns trace.AccessDenied
+// once a limited-use token has already been redeemed its maximum number
+// of times, matching the ephemeral single-use token model used by
+// kubeadm-style cluster joins.
+func CheckOrRedeemToken(token types.ProvisionToken, role types.SystemRole) error {
+	if !token.GetRoles().Include(role) {
+		return trace.AccessDenied("token does not grant role %q", role)
+	}
+
+	v2, ok := token.(*types.ProvisionTokenV2)
+	if !ok {
+		// Older resource versions don't carry MaxUses/UsedCount; nothing
+		// further to enforce.
+		return nil
+	}
+
+	ok, err := v2.Redeem()
+	if !ok {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// RegisterUsingToken is the join-path entry point for a node presenting a
+// static provision token. It looks up tokenName among the cluster's
+// StaticTokens, enforces that it grants role, and redeems one use from
+// tokens with a MaxUses limit before admitting the join.
+func (r *StaticTokensReconciler) RegisterUsingToken(tokenName string, role types.SystemRole) error {
+	current, err := r.Trust.GetStaticTokens()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	// GetStaticTokens returns ProvisionToken values freshly converted from
+	// the stored []ProvisionTokenV1 on every call, so redeeming tok alone
+	// would mutate a disposable copy. The redeemed token must be written
+	// back into the slice and re-persisted via SetStaticTokens, the same
+	// pattern ImportKubernetesBootstrapToken/RemoveKubernetesBootstrapToken
+	// use.
+	tokens := current.GetStaticTokens()
+	for i, tok := range tokens {
+		if tok.GetName() != tokenName {
+			continue
+		}
+		if err := CheckOrRedeemToken(tok, role); err != nil {
+			return trace.Wrap(err)
+		}
+		tokens[i] = tok
+		current.SetStaticTokens(tokens)
+		return trace.Wrap(r.Trust.SetStaticTokens(current))
+	}
+	return trace.AccessDenied("token %q not recognized", tokenName)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOrRedeemTokenEnforcesMaxUses(t *testing.T) {
+	tok := &types.ProvisionTokenV2{
+		Metadata: types.Metadata{Name: "abcdef.0123456789abcdef"},
+		Spec: types.ProvisionTokenSpecV2{
+			Roles:   types.SystemRoles{types.RoleNode},
+			MaxUses: 1,
+		},
+	}
+
+	require.NoError(t, CheckOrRedeemToken(tok, types.RoleNode))
+
+	err := CheckOrRedeemToken(tok, types.RoleNode)
+	require.Error(t, err)
+}
+
+func TestCheckOrRedeemTokenRejectsWrongRole(t *testing.T) {
+	tok := &types.ProvisionTokenV2{
+		Metadata: types.Metadata{Name: "abcdef.0123456789abcdef"},
+		Spec:     types.ProvisionTokenSpecV2{Roles: types.SystemRoles{types.RoleProxy}},
+	}
+
+	err := CheckOrRedeemToken(tok, types.RoleNode)
+	require.Error(t, err)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gravitational/trace"
+)
+
+// StaticTokensReconcilerConfig configures a StaticTokensReconciler.
+type StaticTokensReconcilerConfig struct {
+	// Trust persists the reconciled StaticTokens resource.
+	Trust interface {
+		GetStaticTokens() (types.StaticTokens, error)
+		SetStaticTokens(types.StaticTokens) error
+	}
+	// Emitter emits an audit event for each token removed.
+	Emitter events.Emitter
+	// Clock is used to determine whether a token has expired, and to pace
+	// the reconcile loop. Defaults to the real clock.
+	Clock types.Clock
+	// Period is how often the reconciler walks Spec.StaticTokens looking
+	// for expired entries. Defaults to one minute, matching the interval
+	// the upstream kubeadm bootstrap-token controller polls at.
+	Period time.Duration
+}
+
+// CheckAndSetDefaults validates the config and sets default values.
+func (c *StaticTokensReconcilerConfig) CheckAndSetDefaults() error {
+	if c.Trust == nil {
+		return trace.BadParameter("StaticTokensReconcilerConfig.Trust is required")
+	}
+	if c.Emitter == nil {
+		return trace.BadParameter("StaticTokensReconcilerConfig.Emitter is required")
+	}
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
+	if c.Period == 0 {
+		c.Period = time.Minute
+	}
+	return nil
+}
+
+// StaticTokensReconciler is a controller-style background loop, analogous
+// to the Kubernetes bootstrap-token controller, that periodically prunes
+// expired entries from the StaticTokens singleton resource and emits an
+// audit event for each one removed.
+type StaticTokensReconciler struct {
+	StaticTokensReconcilerConfig
+}
+
+// NewStaticTokensReconciler creates a StaticTokensReconciler from cfg.
+func NewStaticTokensReconciler(cfg StaticTokensReconcilerConfig) (*StaticTokensReconciler, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &StaticTokensReconciler{StaticTokensReconcilerConfig: cfg}, nil
+}
+
+// Run reconciles on Period until ctx is canceled.
+func (r *StaticTokensReconciler) Run(ctx context.Context) error {
+	ticker := r.Clock.NewTicker(r.Period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.Chan():
+			if err := r.reconcile(ctx); err != nil {
+				log.WithError(err).Warn("static tokens reconciler: reconcile failed")
+			}
+		}
+	}
+}
+
+// reconcile removes expired tokens from the StaticTokens resource and
+// re-persists it if anything changed.
+func (r *StaticTokensReconciler) reconcile(ctx context.Context) error {
+	current, err := r.Trust.GetStaticTokens()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	v2, ok := current.(*types.StaticTokensV2)
+	if !ok {
+		return trace.BadParameter("unsupported static tokens resource version %T", current)
+	}
+
+	removed := v2.RemoveExpired(r.Clock)
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := r.Trust.SetStaticTokens(v2); err != nil {
+		return trace.Wrap(err)
+	}
+
+	for _, tok := range removed {
+		if err := r.Emitter.EmitAuditEvent(ctx, &apievents.ProvisionTokenExpire{
+			Metadata: apievents.Metadata{
+				Type: events.ProvisionTokenExpireEvent,
+				Code: events.ProvisionTokenExpireCode,
+			},
+			ResourceMetadata: apievents.ResourceMetadata{
+				Name:    tok.GetName(),
+				Expires: tok.Expiry(),
+			},
+			Roles: tok.GetRoles().StringSlice(),
+		}); err != nil {
+			log.WithError(err).Warn("static tokens reconciler: failed to emit audit event")
+		}
+	}
+	return nil
+}
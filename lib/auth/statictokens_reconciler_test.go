@@ -0,0 +1,155 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	apievents "github.com/gravitational/teleport/api/types/events"
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTrust is an in-memory implementation of the Trust interface used by
+// StaticTokensReconciler and RegisterUsingToken in tests.
+type fakeTrust struct {
+	mu     sync.Mutex
+	tokens types.StaticTokens
+}
+
+func (f *fakeTrust) GetStaticTokens() (types.StaticTokens, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tokens, nil
+}
+
+func (f *fakeTrust) SetStaticTokens(st types.StaticTokens) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = st
+	return nil
+}
+
+// fakeEmitter records emitted audit events for test assertions.
+type fakeEmitter struct {
+	mu     sync.Mutex
+	events []apievents.AuditEvent
+}
+
+func (f *fakeEmitter) EmitAuditEvent(_ context.Context, event apievents.AuditEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeEmitter) emitted() []apievents.AuditEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.events
+}
+
+func newTestStaticTokens(t *testing.T, tokens ...types.ProvisionToken) types.StaticTokens {
+	t.Helper()
+	st, err := types.NewStaticTokens(types.StaticTokensSpecV2{
+		StaticTokens: types.ProvisionTokensToV1(tokens),
+	})
+	require.NoError(t, err)
+	return st
+}
+
+func TestStaticTokensReconcilerPrunesExpiredAndEmitsAuditEvent(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+
+	live, err := types.NewProvisionToken("abcdef.0123456789abcdef", types.SystemRoles{types.RoleNode}, clock.Now().Add(time.Hour))
+	require.NoError(t, err)
+	expired, err := types.NewProvisionToken("ghijkl.fedcba9876543210", types.SystemRoles{types.RoleNode}, clock.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	trust := &fakeTrust{tokens: newTestStaticTokens(t, live, expired)}
+	emitter := &fakeEmitter{}
+
+	r, err := NewStaticTokensReconciler(StaticTokensReconcilerConfig{
+		Trust:   trust,
+		Emitter: emitter,
+		Clock:   clock,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, r.reconcile(context.Background()))
+
+	persisted, err := trust.GetStaticTokens()
+	require.NoError(t, err)
+	require.Len(t, persisted.GetStaticTokens(), 1)
+	require.Equal(t, "abcdef.0123456789abcdef", persisted.GetStaticTokens()[0].GetName())
+
+	emitted := emitter.emitted()
+	require.Len(t, emitted, 1)
+	require.Equal(t, events.ProvisionTokenExpireEvent, emitted[0].GetType())
+}
+
+func TestStaticTokensReconcilerNoopWhenNothingExpired(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+
+	live, err := types.NewProvisionToken("abcdef.0123456789abcdef", types.SystemRoles{types.RoleNode}, clock.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	trust := &fakeTrust{tokens: newTestStaticTokens(t, live)}
+	emitter := &fakeEmitter{}
+
+	r, err := NewStaticTokensReconciler(StaticTokensReconcilerConfig{
+		Trust:   trust,
+		Emitter: emitter,
+		Clock:   clock,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, r.reconcile(context.Background()))
+	require.Empty(t, emitter.emitted())
+
+	persisted, err := trust.GetStaticTokens()
+	require.NoError(t, err)
+	require.Len(t, persisted.GetStaticTokens(), 1)
+}
+
+func TestRegisterUsingTokenPersistsRedemption(t *testing.T) {
+	tok, err := types.NewProvisionToken("abcdef.0123456789abcdef", types.SystemRoles{types.RoleNode}, time.Time{})
+	require.NoError(t, err)
+	tok.(*types.ProvisionTokenV2).SetMaxUses(1)
+
+	trust := &fakeTrust{tokens: newTestStaticTokens(t, tok)}
+	r, err := NewStaticTokensReconciler(StaticTokensReconcilerConfig{
+		Trust:   trust,
+		Emitter: &fakeEmitter{},
+		Clock:   clockwork.NewFakeClock(),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, r.RegisterUsingToken("abcdef.0123456789abcdef", types.RoleNode))
+
+	persisted, err := trust.GetStaticTokens()
+	require.NoError(t, err)
+	require.Equal(t, int32(1), persisted.GetStaticTokens()[0].(*types.ProvisionTokenV2).GetUsedCount())
+
+	err = r.RegisterUsingToken("abcdef.0123456789abcdef", types.RoleNode)
+	require.Error(t, err, "a MaxUses:1 token must reject a second join")
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package token implements a KubernetesTokenSource that keeps Teleport's
+// StaticTokens resource in sync with bootstrap-token Secrets living in a
+// Kubernetes namespace, so that node-join tokens can be managed with
+// kubectl and standard Kubernetes rotation tooling.
+package token
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StaticTokensService is the subset of services.Trust used by
+// KubernetesTokenSource to persist the reconciled StaticTokens resource.
+type StaticTokensService interface {
+	GetStaticTokens() (types.StaticTokens, error)
+	SetStaticTokens(types.StaticTokens) error
+}
+
+// KubernetesTokenSourceConfig configures a KubernetesTokenSource.
+type KubernetesTokenSourceConfig struct {
+	// Client is used to watch Secrets in Namespace.
+	Client kubernetes.Interface
+	// Namespace is the Kubernetes namespace to watch for bootstrap-token
+	// Secrets.
+	Namespace string
+	// UsageRoles maps a bootstrap token "usage-*" key to the Teleport role
+	// it should grant, e.g. {"node-join": types.RoleNode}.
+	UsageRoles map[string]types.SystemRole
+	// StaticTokens persists the reconciled StaticTokens resource.
+	StaticTokens StaticTokensService
+}
+
+// CheckAndSetDefaults validates the config and sets default values.
+func (c *KubernetesTokenSourceConfig) CheckAndSetDefaults() error {
+	if c.Client == nil {
+		return trace.BadParameter("KubernetesTokenSourceConfig.Client is required")
+	}
+	if c.StaticTokens == nil {
+		return trace.BadParameter("KubernetesTokenSourceConfig.StaticTokens is required")
+	}
+	if c.Namespace == "" {
+		c.Namespace = "kube-system"
+	}
+	return nil
+}
+
+// KubernetesTokenSource watches a Kubernetes namespace for
+// bootstrap.kubernetes.io/token Secrets and hot-reloads Teleport's
+// in-memory StaticTokens resource whenever one is added, updated, or
+// deleted.
+type KubernetesTokenSource struct {
+	KubernetesTokenSourceConfig
+	informer cache.SharedIndexInformer
+}
+
+// NewKubernetesTokenSource creates a KubernetesTokenSource from cfg.
+func NewKubernetesTokenSource(cfg KubernetesTokenSourceConfig) (*KubernetesTokenSource, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		cfg.Client,
+		0,
+		informers.WithNamespace(cfg.Namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("type", types.KubernetesBootstrapTokenSecretType).String()
+		}),
+	)
+
+	src := &KubernetesTokenSource{
+		KubernetesTokenSourceConfig: cfg,
+		informer:                    factory.Core().V1().Secrets().Informer(),
+	}
+	src.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { src.onUpsert(obj) },
+		UpdateFunc: func(_, obj interface{}) { src.onUpsert(obj) },
+		DeleteFunc: func(obj interface{}) { src.onDelete(obj) },
+	})
+	return src, nil
+}
+
+// Run starts watching until ctx is canceled.
+func (s *KubernetesTokenSource) Run(ctx context.Context) {
+	s.informer.Run(ctx.Done())
+}
+
+// onUpsert reconciles the in-memory StaticTokens with the current state of
+// a single bootstrap-token Secret that was added or updated.
+func (s *KubernetesTokenSource) onUpsert(obj interface{}) {
+	secret, ok := unwrapSecret(obj)
+	if !ok {
+		return
+	}
+
+	id, ok := secret.Data["token-id"]
+	if !ok {
+		return
+	}
+
+	current, err := s.StaticTokens.GetStaticTokens()
+	if err != nil {
+		log.WithError(err).Warn("kubernetes token source: failed to load static tokens")
+		return
+	}
+
+	marshaler := types.GetStaticTokensMarshaler()
+	updated, err := marshaler.ImportKubernetesBootstrapToken(current, string(id), secret.Data, s.UsageRoles)
+	if err != nil {
+		log.WithError(err).Warnf("kubernetes token source: failed to import bootstrap token %v/%v", secret.Namespace, secret.Name)
+		return
+	}
+
+	if err := s.StaticTokens.SetStaticTokens(updated); err != nil {
+		log.WithError(err).Warn("kubernetes token source: failed to persist static tokens")
+	}
+}
+
+// onDelete revokes the static token sourced from a bootstrap-token Secret
+// that was deleted, so that removing the Secret from the cluster actually
+// removes the corresponding Teleport join token instead of leaving the
+// last-known copy in place.
+func (s *KubernetesTokenSource) onDelete(obj interface{}) {
+	secret, ok := unwrapSecret(obj)
+	if !ok {
+		return
+	}
+
+	id, ok := secret.Data["token-id"]
+	if !ok {
+		return
+	}
+
+	current, err := s.StaticTokens.GetStaticTokens()
+	if err != nil {
+		log.WithError(err).Warn("kubernetes token source: failed to load static tokens")
+		return
+	}
+
+	marshaler := types.GetStaticTokensMarshaler()
+	updated, err := marshaler.RemoveKubernetesBootstrapToken(current, string(id))
+	if err != nil {
+		log.WithError(err).Warnf("kubernetes token source: failed to remove bootstrap token %v/%v", secret.Namespace, secret.Name)
+		return
+	}
+
+	if err := s.StaticTokens.SetStaticTokens(updated); err != nil {
+		log.WithError(err).Warn("kubernetes token source: failed to persist static tokens")
+	}
+}
+
+// unwrapSecret extracts a *corev1.Secret from an informer event object,
+// handling the cache.DeletedFinalStateUnknown tombstone client-go delivers
+// when a delete event is missed and only discovered on relist.
+func unwrapSecret(obj interface{}) (*corev1.Secret, bool) {
+	switch v := obj.(type) {
+	case *corev1.Secret:
+		return v, true
+	case cache.DeletedFinalStateUnknown:
+		secret, ok := v.Obj.(*corev1.Secret)
+		return secret, ok
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeStaticTokensService is an in-memory StaticTokensService for tests.
+type fakeStaticTokensService struct {
+	mu     sync.Mutex
+	tokens types.StaticTokens
+}
+
+func (f *fakeStaticTokensService) GetStaticTokens() (types.StaticTokens, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tokens, nil
+}
+
+func (f *fakeStaticTokensService) SetStaticTokens(st types.StaticTokens) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = st
+	return nil
+}
+
+func (f *fakeStaticTokensService) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.tokens.GetStaticTokens())
+}
+
+func newBootstrapTokenSecret(name, id, secret string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kube-system",
+		},
+		Type: corev1.SecretType(types.KubernetesBootstrapTokenSecretType),
+		Data: map[string][]byte{
+			"token-id":        []byte(id),
+			"token-secret":    []byte(secret),
+			"usage-node-join": []byte("true"),
+		},
+	}
+}
+
+func TestKubernetesTokenSourceAddDelete(t *testing.T) {
+	svc := &fakeStaticTokensService{tokens: types.DefaultStaticTokens()}
+	client := fake.NewSimpleClientset()
+
+	src, err := NewKubernetesTokenSource(KubernetesTokenSourceConfig{
+		Client:       client,
+		Namespace:    "kube-system",
+		UsageRoles:   map[string]types.SystemRole{"node-join": types.RoleNode},
+		StaticTokens: svc,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go src.Run(ctx)
+
+	secret := newBootstrapTokenSecret("bootstrap-token-abcdef", "abcdef", "0123456789abcdef")
+	_, err = client.CoreV1().Secrets("kube-system").Create(ctx, secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return svc.count() == 1
+	}, time.Second, 10*time.Millisecond, "expected secret create to import a static token")
+
+	err = client.CoreV1().Secrets("kube-system").Delete(ctx, secret.Name, metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return svc.count() == 0
+	}, time.Second, 10*time.Millisecond, "expected secret delete to revoke the static token")
+}
+
+func TestKubernetesTokenSourceDeleteHandlesTombstone(t *testing.T) {
+	svc := &fakeStaticTokensService{tokens: types.DefaultStaticTokens()}
+	client := fake.NewSimpleClientset()
+
+	src, err := NewKubernetesTokenSource(KubernetesTokenSourceConfig{
+		Client:       client,
+		Namespace:    "kube-system",
+		UsageRoles:   map[string]types.SystemRole{"node-join": types.RoleNode},
+		StaticTokens: svc,
+	})
+	require.NoError(t, err)
+
+	secret := newBootstrapTokenSecret("bootstrap-token-abcdef", "abcdef", "0123456789abcdef")
+	current, err := types.GetStaticTokensMarshaler().ImportKubernetesBootstrapToken(
+		svc.tokens, "abcdef", secret.Data, src.UsageRoles)
+	require.NoError(t, err)
+	require.NoError(t, svc.SetStaticTokens(current))
+	require.Equal(t, 1, svc.count())
+
+	src.onDelete(cache.DeletedFinalStateUnknown{Key: "kube-system/bootstrap-token-abcdef", Obj: secret})
+
+	require.Equal(t, 0, svc.count())
+}